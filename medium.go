@@ -5,17 +5,22 @@ package medium
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math/rand"
 	"mime/multipart"
 	"net/http"
 	"net/textproto"
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -60,6 +65,10 @@ const (
 	defaultTimeout = 5 * time.Second
 	// defaultCode is the default error code for failures.
 	defaultCode = -1
+	// retryBaseDelay is the starting delay used by defaultRetryBackoff.
+	retryBaseDelay = 250 * time.Millisecond
+	// retryMaxDelay caps the delay produced by defaultRetryBackoff.
+	retryMaxDelay = 30 * time.Second
 )
 
 // formats used for marshalling data for requests.
@@ -90,7 +99,28 @@ type CreatePostOptions struct {
 type UploadOptions struct {
 	FilePath    string
 	ContentType string
-	fieldName   string
+
+	// Reader, if set, is read to produce the upload body instead of
+	// opening FilePath from disk. Size should also be set when known,
+	// so its value is reported as the total in Progress callbacks. If
+	// Reader implements io.Closer but not io.Seeker it is closed once
+	// fully read; a Reader that implements io.Seeker is left open, since
+	// a retry reads it again. If a
+	// request needs to be retried (see Medium.MaxRetries), Reader is
+	// rewound with io.Seeker before the retry; a Reader that doesn't
+	// implement io.Seeker disables retries for that upload, since it
+	// can't be replayed once partially consumed.
+	Reader io.Reader
+	// Size is the total number of bytes that will be read from Reader.
+	// It is ignored when Reader is unset, since the file's size on
+	// disk is used instead.
+	Size int64
+	// Progress, if set, is called after every chunk written to the
+	// wire with the cumulative bytes sent and the total size, so
+	// callers can report upload progress.
+	Progress func(sent, total int64)
+
+	fieldName string
 }
 
 // AccessToken defines credentials with which Medium's API may be accessed.
@@ -156,6 +186,38 @@ type Image struct {
 	MD5 string `json:"md5"`
 }
 
+// UpdatePostOptions defines the options for updating a post on Medium.
+// Zero-valued fields are omitted from the request, leaving the
+// corresponding property on the post unchanged.
+type UpdatePostOptions struct {
+	PostID        string        `json:"-"`
+	Title         string        `json:"title,omitempty"`
+	Content       string        `json:"content,omitempty"`
+	ContentFormat ContentFormat `json:"contentFormat,omitempty"`
+	Tags          []string      `json:"tags,omitempty"`
+	CanonicalURL  string        `json:"canonicalUrl,omitempty"`
+	PublishStatus PublishStatus `json:"publishStatus,omitempty"`
+	License       License       `json:"license,omitempty"`
+}
+
+// ListOptions controls pagination for list endpoints.
+type ListOptions struct {
+	// Limit caps the number of posts returned in a page. A zero value
+	// leaves it up to the API's default.
+	Limit int
+	// Cursor resumes listing from a PostList.NextCursor returned by a
+	// previous call. Leave empty to start from the first page.
+	Cursor string
+}
+
+// PostList is a single page of posts returned by a list endpoint.
+type PostList struct {
+	Posts []Post
+	// NextCursor is passed as ListOptions.Cursor to fetch the next
+	// page. It is empty once the last page has been reached.
+	NextCursor string
+}
+
 // Error defines an error received when making a request to the API.
 type Error struct {
 	Message string `json:"message"`
@@ -167,6 +229,93 @@ func (e Error) Error() string {
 	return fmt.Sprintf("medium: %s (%d)", e.Message, e.Code)
 }
 
+// RateLimit carries the rate-limit metadata Medium reports on a
+// response, parsed from its X-RateLimit-* headers; Reset falls back to
+// Retry-After when X-RateLimit-Reset isn't present.
+type RateLimit struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time
+}
+
+// APIError wraps an Error with the HTTP-level metadata needed to make
+// retry and re-authentication decisions, instead of string-matching on
+// err.Error(): the status code, response headers, raw body, and any
+// rate-limit metadata.
+type APIError struct {
+	Err        Error
+	HTTPStatus int
+	Header     http.Header
+	RawBody    []byte
+	RateLimit  RateLimit
+}
+
+// Error satisfies the error interface, delegating to the wrapped Error.
+func (e APIError) Error() string { return e.Err.Error() }
+
+// Unwrap lets errors.As/errors.Is see through to the wrapped Error.
+func (e APIError) Unwrap() error { return e.Err }
+
+// Is lets errors.Is(err, ErrTokenExpired) and errors.Is(err,
+// ErrInvalidScope) match an APIError based on its HTTP status, without
+// requiring the caller to compare HTTPStatus by hand.
+func (e APIError) Is(target error) bool {
+	switch target {
+	case ErrTokenExpired:
+		return e.HTTPStatus == http.StatusUnauthorized
+	case ErrInvalidScope:
+		return e.HTTPStatus == http.StatusForbidden
+	}
+	return false
+}
+
+// IsUnauthorized reports whether the request failed because the access
+// token was missing, invalid, or expired.
+func (e APIError) IsUnauthorized() bool { return e.HTTPStatus == http.StatusUnauthorized }
+
+// IsRateLimited reports whether the request failed because of Medium's
+// rate limiting.
+func (e APIError) IsRateLimited() bool { return e.HTTPStatus == http.StatusTooManyRequests }
+
+// IsNotFound reports whether the requested resource doesn't exist.
+func (e APIError) IsNotFound() bool { return e.HTTPStatus == http.StatusNotFound }
+
+// IsServerError reports whether the failure was on Medium's end.
+func (e APIError) IsServerError() bool { return e.HTTPStatus >= http.StatusInternalServerError }
+
+// Sentinel errors usable with errors.Is, matched against an APIError's
+// HTTPStatus by APIError.Is.
+var (
+	// ErrTokenExpired indicates the AccessToken used for a request was
+	// rejected as expired or invalid, and should be refreshed.
+	ErrTokenExpired = errors.New("medium: access token expired")
+	// ErrInvalidScope indicates the AccessToken doesn't carry a scope
+	// required by the requested operation.
+	ErrInvalidScope = errors.New("medium: access token missing required scope")
+)
+
+// parseRateLimit extracts rate-limit metadata from h, returning the
+// zero value if neither Medium's X-RateLimit-* headers nor Retry-After
+// are present. Reset is taken from X-RateLimit-Reset, or from
+// Retry-After when X-RateLimit-Reset is absent.
+func parseRateLimit(h http.Header) RateLimit {
+	var rl RateLimit
+	if v := h.Get("X-RateLimit-Limit"); v != "" {
+		rl.Limit, _ = strconv.Atoi(v)
+	}
+	if v := h.Get("X-RateLimit-Remaining"); v != "" {
+		rl.Remaining, _ = strconv.Atoi(v)
+	}
+	if v := h.Get("X-RateLimit-Reset"); v != "" {
+		if secs, err := strconv.ParseInt(v, 10, 64); err == nil {
+			rl.Reset = time.Unix(secs, 0)
+		}
+	} else if d := parseRetryAfter(h.Get("Retry-After")); d > 0 {
+		rl.Reset = time.Now().Add(d)
+	}
+	return rl
+}
+
 // Medium defines the Medium client.
 type Medium struct {
 	ApplicationID     string
@@ -175,7 +324,24 @@ type Medium struct {
 	Host              string
 	Timeout           time.Duration
 	Transport         http.RoundTripper
-	fs                fileOpener
+
+	// MaxRetries is the number of times a request is retried after a
+	// transient failure (network error, 5xx response, or 429 rate
+	// limit). It defaults to zero, so retries are opt-in.
+	MaxRetries int
+	// RetryBackoff computes the delay before the given retry attempt
+	// (0-indexed). If nil, defaultRetryBackoff is used.
+	RetryBackoff func(attempt int) time.Duration
+	// RetryableStatusCodes are the HTTP status codes that trigger a
+	// retry. If nil, defaultRetryableStatusCodes is used.
+	RetryableStatusCodes map[int]bool
+
+	// TokenSource, if set, supplies the bearer token for every request
+	// instead of the static AccessToken field, refreshing it as needed.
+	// See NewClientWithTokenSource and NewTokenSource.
+	TokenSource TokenSource
+
+	fs fileOpener
 }
 
 // NewClient returns a new Medium API client which can be used to make RPC requests.
@@ -199,6 +365,96 @@ func NewClientWithAccessToken(accessToken string) *Medium {
 	}
 }
 
+// NewClientWithTokenSource returns a new Medium API client that obtains
+// its bearer token from ts on every request instead of a static
+// AccessToken, so a token nearing expiry can be refreshed automatically.
+// See NewTokenSource for the default TokenSource implementation.
+func NewClientWithTokenSource(id, secret string, ts TokenSource) *Medium {
+	return &Medium{
+		ApplicationID:     id,
+		ApplicationSecret: secret,
+		Host:              host,
+		Timeout:           defaultTimeout,
+		Transport:         http.DefaultTransport,
+		TokenSource:       ts,
+		fs:                osFS{},
+	}
+}
+
+// bearerToken returns the access token to send in the Authorization
+// header, preferring m.TokenSource when set and falling back to the
+// static m.AccessToken otherwise for backward compatibility.
+func (m *Medium) bearerToken() (string, error) {
+	if m.TokenSource == nil {
+		return m.AccessToken, nil
+	}
+	at, err := m.TokenSource.Token()
+	if err != nil {
+		return "", err
+	}
+	return at.AccessToken, nil
+}
+
+// TokenSource supplies the bearer token used to authenticate requests to
+// Medium's API, refreshing it as needed.
+type TokenSource interface {
+	// Token returns a valid access token, refreshing it first if it's
+	// at or past its expiration skew.
+	Token() (AccessToken, error)
+}
+
+// defaultRefreshSkew is the window before a token's expiration at which
+// NewTokenSource proactively refreshes it.
+const defaultRefreshSkew = 60 * time.Second
+
+// NewTokenSource returns a TokenSource that serves at, automatically
+// exchanging at.RefreshToken for a new AccessToken via m once at is
+// within skew of expiring (a skew of zero uses defaultRefreshSkew). The
+// returned TokenSource is safe for concurrent use.
+func NewTokenSource(m *Medium, at AccessToken, skew time.Duration) TokenSource {
+	if skew <= 0 {
+		skew = defaultRefreshSkew
+	}
+	return &refreshingTokenSource{m: m, token: at, refreshSkew: skew}
+}
+
+// refreshingTokenSource is the default TokenSource, returned by
+// NewTokenSource. It refreshes its AccessToken automatically when it's
+// within refreshSkew of expiring.
+type refreshingTokenSource struct {
+	m           *Medium
+	refreshSkew time.Duration
+
+	mu    sync.Mutex
+	token AccessToken
+}
+
+// Token implements TokenSource.
+func (s *refreshingTokenSource) Token() (AccessToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.needsRefresh() {
+		return s.token, nil
+	}
+	at, err := s.m.ExchangeRefreshToken(s.token.RefreshToken)
+	if err != nil {
+		return AccessToken{}, err
+	}
+	s.token = at
+	return s.token, nil
+}
+
+// needsRefresh reports whether s.token is close enough to expiring (or
+// already has) that it should be refreshed before being handed out.
+func (s *refreshingTokenSource) needsRefresh() bool {
+	if s.token.RefreshToken == "" || s.token.ExpiresAt == 0 {
+		return false
+	}
+	expiresAt := time.Unix(s.token.ExpiresAt/1000, 0)
+	return !time.Now().Add(s.refreshSkew).Before(expiresAt)
+}
+
 // GetAuthorizationURL returns the URL to which an application may send
 // a user in order to acquire authorization.
 func (m *Medium) GetAuthorizationURL(state, redirectURL string, scopes ...Scope) string {
@@ -218,6 +474,28 @@ func (m *Medium) GetAuthorizationURL(state, redirectURL string, scopes ...Scope)
 
 // ExchangeAuthorizationCode exchanges the supplied code for a long-lived access token.
 func (m *Medium) ExchangeAuthorizationCode(code, redirectURL string) (AccessToken, error) {
+	return m.ExchangeAuthorizationCodeContext(context.Background(), code, redirectURL)
+}
+
+// ExchangeAuthorizationCodeContext is like ExchangeAuthorizationCode but
+// takes a context.Context to allow the request to be cancelled or bounded
+// by a deadline.
+func (m *Medium) ExchangeAuthorizationCodeContext(ctx context.Context, code, redirectURL string) (AccessToken, error) {
+	return m.exchangeAuthorizationCode(ctx, code, redirectURL, "")
+}
+
+// ExchangeAuthorizationCodeWithVerifier is like
+// ExchangeAuthorizationCodeContext, but also sends verifier as the PKCE
+// code_verifier, as required when the authorization request (see
+// GetAuthorizationURL) included a code_challenge derived from it.
+func (m *Medium) ExchangeAuthorizationCodeWithVerifier(ctx context.Context, code, redirectURL, verifier string) (AccessToken, error) {
+	return m.exchangeAuthorizationCode(ctx, code, redirectURL, verifier)
+}
+
+// exchangeAuthorizationCode implements ExchangeAuthorizationCodeContext
+// and ExchangeAuthorizationCodeWithVerifier, including code_verifier in
+// the exchange only when verifier is non-empty.
+func (m *Medium) exchangeAuthorizationCode(ctx context.Context, code, redirectURL, verifier string) (AccessToken, error) {
 	v := url.Values{
 		"code":          {code},
 		"client_id":     {m.ApplicationID},
@@ -225,24 +503,40 @@ func (m *Medium) ExchangeAuthorizationCode(code, redirectURL string) (AccessToke
 		"grant_type":    {"authorization_code"},
 		"redirect_uri":  {redirectURL},
 	}
-	return m.acquireAccessToken(v)
+	if verifier != "" {
+		v.Set("code_verifier", verifier)
+	}
+	return m.acquireAccessToken(ctx, v)
 }
 
 // ExchangeRefreshToken exchanges the supplied refresh token for a new access token.
 func (m *Medium) ExchangeRefreshToken(rt string) (AccessToken, error) {
+	return m.ExchangeRefreshTokenContext(context.Background(), rt)
+}
+
+// ExchangeRefreshTokenContext is like ExchangeRefreshToken but takes a
+// context.Context to allow the request to be cancelled or bounded by a
+// deadline.
+func (m *Medium) ExchangeRefreshTokenContext(ctx context.Context, rt string) (AccessToken, error) {
 	v := url.Values{
 		"refresh_token": {rt},
 		"client_id":     {m.ApplicationID},
 		"client_secret": {m.ApplicationSecret},
 		"grant_type":    {"refresh_token"},
 	}
-	return m.acquireAccessToken(v)
+	return m.acquireAccessToken(ctx, v)
 }
 
 // GetUser gets the profile identified by the current AccessToken.
 // It will get the specified user or the current user if userID is empty.
 // This requires m.AccessToken to have the BasicProfile scope.
 func (m *Medium) GetUser(userID string) (*User, error) {
+	return m.GetUserContext(context.Background(), userID)
+}
+
+// GetUserContext is like GetUser but takes a context.Context to allow the
+// request to be cancelled or bounded by a deadline.
+func (m *Medium) GetUserContext(ctx context.Context, userID string) (*User, error) {
 	var r clientRequest
 	if userID == "" {
 		r = clientRequest{
@@ -256,19 +550,26 @@ func (m *Medium) GetUser(userID string) (*User, error) {
 		}
 	}
 	u := &User{}
-	err := m.request(r, u)
+	err := m.requestContext(ctx, r, u)
 	return u, err
 }
 
 // GetUserPublications gets user publications by the current AccessToken.
 // This requires m.AccessToken to have the BasicPublications scope.
 func (m *Medium) GetUserPublications(userID string) (*Publications, error) {
+	return m.GetUserPublicationsContext(context.Background(), userID)
+}
+
+// GetUserPublicationsContext is like GetUserPublications but takes a
+// context.Context to allow the request to be cancelled or bounded by a
+// deadline.
+func (m *Medium) GetUserPublicationsContext(ctx context.Context, userID string) (*Publications, error) {
 	r := clientRequest{
 		method: "GET",
 		path:   fmt.Sprintf("/v1/users/%s/publications", userID),
 	}
 	p := &Publications{}
-	err := m.request(r, p)
+	err := m.requestContext(ctx, r, p)
 	return p, err
 }
 
@@ -276,31 +577,177 @@ func (m *Medium) GetUserPublications(userID string) (*Publications, error) {
 // by the current AccessToken.
 // This requires m.AccessToken to have the BasicPublications scope.
 func (m *Medium) GetPublicationContributors(publicationID string) (*Contributors, error) {
+	return m.GetPublicationContributorsContext(context.Background(), publicationID)
+}
+
+// GetPublicationContributorsContext is like GetPublicationContributors but
+// takes a context.Context to allow the request to be cancelled or bounded
+// by a deadline.
+func (m *Medium) GetPublicationContributorsContext(ctx context.Context, publicationID string) (*Contributors, error) {
 	r := clientRequest{
 		method: "GET",
 		path:   fmt.Sprintf("/v1/publications/%s/contributors", publicationID),
 	}
 	p := &Contributors{}
-	err := m.request(r, p)
+	err := m.requestContext(ctx, r, p)
 	return p, err
 }
 
 // CreatePost creates a post on the profile identified by the current AccessToken.
 // This requires m.AccessToken to have the PublishPost scope.
 func (m *Medium) CreatePost(o CreatePostOptions) (*Post, error) {
+	return m.CreatePostContext(context.Background(), o)
+}
+
+// CreatePostContext is like CreatePost but takes a context.Context to allow
+// the request to be cancelled or bounded by a deadline.
+func (m *Medium) CreatePostContext(ctx context.Context, o CreatePostOptions) (*Post, error) {
 	r := clientRequest{
 		method: "POST",
 		path:   fmt.Sprintf("/v1/users/%s/posts", o.UserID),
 		data:   o,
 	}
 	p := &Post{}
-	err := m.request(r, p)
+	err := m.requestContext(ctx, r, p)
+	return p, err
+}
+
+// CreatePostInPublication creates a post under the given publication.
+// This requires m.AccessToken to have the PublishPost scope and the
+// token's user to be a contributor on the publication.
+func (m *Medium) CreatePostInPublication(publicationID string, o CreatePostOptions) (*Post, error) {
+	return m.CreatePostInPublicationContext(context.Background(), publicationID, o)
+}
+
+// CreatePostInPublicationContext is like CreatePostInPublication but
+// takes a context.Context to allow the request to be cancelled or
+// bounded by a deadline.
+func (m *Medium) CreatePostInPublicationContext(ctx context.Context, publicationID string, o CreatePostOptions) (*Post, error) {
+	r := clientRequest{
+		method: "POST",
+		path:   fmt.Sprintf("/v1/publications/%s/posts", publicationID),
+		data:   o,
+	}
+	p := &Post{}
+	err := m.requestContext(ctx, r, p)
+	return p, err
+}
+
+// GetPost gets a post by ID.
+// This requires m.AccessToken to have the BasicProfile scope.
+func (m *Medium) GetPost(postID string) (*Post, error) {
+	return m.GetPostContext(context.Background(), postID)
+}
+
+// GetPostContext is like GetPost but takes a context.Context to allow
+// the request to be cancelled or bounded by a deadline.
+func (m *Medium) GetPostContext(ctx context.Context, postID string) (*Post, error) {
+	r := clientRequest{
+		method: "GET",
+		path:   fmt.Sprintf("/v1/posts/%s", postID),
+	}
+	p := &Post{}
+	err := m.requestContext(ctx, r, p)
 	return p, err
 }
 
+// UpdatePost updates the post identified by o.PostID.
+// This requires m.AccessToken to have the PublishPost scope.
+func (m *Medium) UpdatePost(o UpdatePostOptions) (*Post, error) {
+	return m.UpdatePostContext(context.Background(), o)
+}
+
+// UpdatePostContext is like UpdatePost but takes a context.Context to
+// allow the request to be cancelled or bounded by a deadline.
+func (m *Medium) UpdatePostContext(ctx context.Context, o UpdatePostOptions) (*Post, error) {
+	r := clientRequest{
+		method: "PUT",
+		path:   fmt.Sprintf("/v1/posts/%s", o.PostID),
+		data:   o,
+	}
+	p := &Post{}
+	err := m.requestContext(ctx, r, p)
+	return p, err
+}
+
+// DeletePost deletes the post identified by postID.
+// This requires m.AccessToken to have the PublishPost scope.
+func (m *Medium) DeletePost(postID string) error {
+	return m.DeletePostContext(context.Background(), postID)
+}
+
+// DeletePostContext is like DeletePost but takes a context.Context to
+// allow the request to be cancelled or bounded by a deadline.
+func (m *Medium) DeletePostContext(ctx context.Context, postID string) error {
+	r := clientRequest{
+		method: "DELETE",
+		path:   fmt.Sprintf("/v1/posts/%s", postID),
+	}
+	return m.requestContext(ctx, r, nil)
+}
+
+// ListUserPosts lists the posts authored by userID.
+// This requires m.AccessToken to have the BasicProfile scope.
+func (m *Medium) ListUserPosts(userID string, o ListOptions) (*PostList, error) {
+	return m.ListUserPostsContext(context.Background(), userID, o)
+}
+
+// ListUserPostsContext is like ListUserPosts but takes a context.Context
+// to allow the request to be cancelled or bounded by a deadline.
+func (m *Medium) ListUserPostsContext(ctx context.Context, userID string, o ListOptions) (*PostList, error) {
+	return m.listPosts(ctx, fmt.Sprintf("/v1/users/%s/posts", userID), o)
+}
+
+// ListPublicationPosts lists the posts published under publicationID.
+// This requires m.AccessToken to have the BasicPublications scope.
+func (m *Medium) ListPublicationPosts(publicationID string, o ListOptions) (*PostList, error) {
+	return m.ListPublicationPostsContext(context.Background(), publicationID, o)
+}
+
+// ListPublicationPostsContext is like ListPublicationPosts but takes a
+// context.Context to allow the request to be cancelled or bounded by a
+// deadline.
+func (m *Medium) ListPublicationPostsContext(ctx context.Context, publicationID string, o ListOptions) (*PostList, error) {
+	return m.listPosts(ctx, fmt.Sprintf("/v1/publications/%s/posts", publicationID), o)
+}
+
+// listPosts fetches a single page of posts from path, applying o's
+// pagination parameters as a query string.
+func (m *Medium) listPosts(ctx context.Context, path string, o ListOptions) (*PostList, error) {
+	v := url.Values{}
+	if o.Limit > 0 {
+		v.Set("limit", strconv.Itoa(o.Limit))
+	}
+	if o.Cursor != "" {
+		v.Set("cursor", o.Cursor)
+	}
+	if len(v) > 0 {
+		path = path + "?" + v.Encode()
+	}
+
+	var next string
+	r := clientRequest{
+		method:     "GET",
+		path:       path,
+		nextCursor: &next,
+	}
+	var posts []Post
+	if err := m.requestContext(ctx, r, &posts); err != nil {
+		return nil, err
+	}
+	return &PostList{Posts: posts, NextCursor: next}, nil
+}
+
 // UploadImage uploads an image to Medium.
 // This requires m.AccessToken to have the UploadImage scope.
 func (m *Medium) UploadImage(o UploadOptions) (*Image, error) {
+	return m.UploadImageContext(context.Background(), o)
+}
+
+// UploadImageContext is like UploadImage but takes a context.Context to
+// allow the request to be cancelled or bounded by a deadline, so that
+// long-running uploads can be aborted by the caller.
+func (m *Medium) UploadImageContext(ctx context.Context, o UploadOptions) (*Image, error) {
 	o.fieldName = "image"
 	r := clientRequest{
 		method: "POST",
@@ -309,21 +756,21 @@ func (m *Medium) UploadImage(o UploadOptions) (*Image, error) {
 		data:   o,
 	}
 	i := &Image{}
-	err := m.request(r, i)
+	err := m.requestContext(ctx, r, i)
 	return i, err
 }
 
 // generateJSONRequestData returns the body and content type for a JSON request.
-func (m *Medium) generateJSONRequestData(cr clientRequest) ([]byte, string, error) {
+func (m *Medium) generateJSONRequestData(cr clientRequest) (io.Reader, string, error) {
 	body, err := json.Marshal(cr.data)
 	if err != nil {
 		return nil, "", Error{fmt.Sprintf("Could not marshal JSON: %s", err), defaultCode}
 	}
-	return body, "application/json", nil
+	return bytes.NewReader(body), "application/json", nil
 }
 
 // generateFormRequestData returns the body and content type for a form data request.
-func (m *Medium) generateFormRequestData(cr clientRequest) ([]byte, string, error) {
+func (m *Medium) generateFormRequestData(cr clientRequest) (io.Reader, string, error) {
 	var body []byte
 	switch d := cr.data.(type) {
 	case string:
@@ -333,49 +780,119 @@ func (m *Medium) generateFormRequestData(cr clientRequest) ([]byte, string, erro
 	default:
 		return nil, "", Error{"Invalid data passed for form request", defaultCode}
 	}
-	return body, "application/x-www-form-urlencoded", nil
+	return bytes.NewReader(body), "application/x-www-form-urlencoded", nil
 }
 
-// generateFileRequestData returns the body and content type for a file upload request.
-func (m *Medium) generateFileRequestData(cr clientRequest) ([]byte, string, error) {
+// generateFileRequestData returns a reader that streams the multipart
+// body for a file upload request, along with its content type. The
+// multipart encoding happens on the fly in a goroutine writing into an
+// io.Pipe, so the whole file is never buffered in memory; this is what
+// lets UploadImage(Context) handle arbitrarily large files.
+func (m *Medium) generateFileRequestData(cr clientRequest) (io.Reader, string, error) {
 	uo, ok := cr.data.(UploadOptions)
 	if !ok {
 		return nil, "", Error{"Invalid data passed for file upload", defaultCode}
 	}
-	file, err := m.fs.Open(uo.FilePath)
-	if err != nil {
-		return nil, "", Error{fmt.Sprintf("Could not open file: %s", err), defaultCode}
-	}
-	defer file.Close()
-
-	// Create a form part
-	b := bytes.Buffer{}
-	w := multipart.NewWriter(&b)
-	h := make(textproto.MIMEHeader)
-	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
-		escapeQuotes(uo.fieldName), escapeQuotes(filepath.Base(uo.FilePath))))
-	h.Set("Content-Type", uo.ContentType)
-	part, err := w.CreatePart(h)
-	if err != nil {
-		return nil, "", Error{fmt.Sprintf("Could not create form part: %s", err), defaultCode}
-	}
-	_, err = io.Copy(part, file)
-	if err != nil {
-		return nil, "", Error{fmt.Sprintf("Could not copy data: %s", err), defaultCode}
+
+	src := uo.Reader
+	size := uo.Size
+	filename := filepath.Base(uo.FilePath)
+	if src == nil {
+		file, err := m.fs.Open(uo.FilePath)
+		if err != nil {
+			return nil, "", Error{fmt.Sprintf("Could not open file: %s", err), defaultCode}
+		}
+		src = file
+		if f, ok := file.(*os.File); ok {
+			if info, err := f.Stat(); err == nil {
+				size = info.Size()
+			}
+		}
+	} else if sk, ok := src.(io.Seeker); ok {
+		// This generator is called again from scratch on each retry
+		// attempt, but a caller-supplied Reader (unlike a freshly opened
+		// disk file) is the same object every time, so rewind it back to
+		// the start before it's read again.
+		if _, err := sk.Seek(0, io.SeekStart); err != nil {
+			return nil, "", Error{fmt.Sprintf("Could not rewind upload reader: %s", err), defaultCode}
+		}
 	}
-	w.Close()
 
-	return b.Bytes(), w.FormDataContentType(), nil
+	pr, pw := io.Pipe()
+	w := multipart.NewWriter(pw)
+
+	go func() {
+		// A caller-supplied seekable Reader is left open so that a retry
+		// can rewind and read it again.
+		_, seekable := src.(io.Seeker)
+		if c, ok := src.(io.Closer); ok && (uo.Reader == nil || !seekable) {
+			defer c.Close()
+		}
+
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+			escapeQuotes(uo.fieldName), escapeQuotes(filename)))
+		h.Set("Content-Type", uo.ContentType)
+		part, err := w.CreatePart(h)
+		if err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+
+		r := src
+		if uo.Progress != nil {
+			r = &progressReader{r: src, total: size, progress: uo.Progress}
+		}
+		if _, err := io.Copy(part, r); err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		if err := w.Close(); err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		pw.Close()
+	}()
+
+	return pr, w.FormDataContentType(), nil
+}
+
+// progressReader wraps an io.Reader, reporting cumulative bytes read to
+// progress as the upload is streamed to the wire.
+type progressReader struct {
+	r        io.Reader
+	sent     int64
+	total    int64
+	progress func(sent, total int64)
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		p.sent += int64(n)
+		p.progress(p.sent, p.total)
+	}
+	return n, err
 }
 
 // request makes a request to Medium's API
 func (m *Medium) request(cr clientRequest, result interface{}) error {
+	return m.requestContext(context.Background(), cr, result)
+}
+
+// requestContext makes a request to Medium's API, using ctx to control
+// cancellation and deadlines. Transient failures (network errors, 5xx
+// responses, and 429 rate limits) are retried up to m.MaxRetries times,
+// honoring any Retry-After header before falling back to m.RetryBackoff.
+func (m *Medium) requestContext(ctx context.Context, cr clientRequest, result interface{}) error {
 	f := cr.format
 	if f == "" {
 		f = formatJSON
 	}
 
-	// Get the body and content type.
+	// Select the function that generates the body and content type. It
+	// is called once per attempt (rather than once up front) so that a
+	// streaming file upload can be re-opened from the start on retry.
 	var g requestDataGenerator
 	switch f {
 	case formatJSON:
@@ -387,21 +904,71 @@ func (m *Medium) request(cr clientRequest, result interface{}) error {
 	default:
 		return Error{fmt.Sprintf("Unknown format: %s", cr.format), defaultCode}
 	}
-	body, ct, err := g(cr)
-	if err != nil {
-		return err
+
+	maxRetries := m.MaxRetries
+	if f == formatFile {
+		if uo, ok := cr.data.(UploadOptions); ok && uo.Reader != nil {
+			if _, seekable := uo.Reader.(io.Seeker); !seekable {
+				// generateFileRequestData can only rewind a caller-supplied
+				// Reader on retry if it implements io.Seeker. Without that,
+				// retrying would resend whatever was left of the
+				// already-consumed Reader, silently uploading a truncated
+				// or empty file, so don't retry this request at all.
+				maxRetries = 0
+			}
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		body, ct, err := g(cr)
+		if err != nil {
+			return err
+		}
+
+		statusCode, retryAfter, err := m.doRequest(ctx, cr, body, ct, result)
+		if err == nil || attempt >= maxRetries || !m.isRetryable(statusCode, err) {
+			return err
+		}
+
+		wait := retryAfter
+		if wait <= 0 {
+			wait = m.retryBackoff()(attempt)
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
 	}
+}
 
+// doRequest performs a single attempt of cr against Medium's API. It
+// returns the HTTP status code observed (0 if the request never reached
+// the server) and the Retry-After duration parsed from the response, if
+// any, so that requestContext's retry loop can make use of them.
+func (m *Medium) doRequest(ctx context.Context, cr clientRequest, body io.Reader, ct string, result interface{}) (int, time.Duration, error) {
 	// Construct the request
-	req, err := http.NewRequest(cr.method, m.Host+cr.path, bytes.NewReader(body))
+	req, err := http.NewRequestWithContext(ctx, cr.method, m.Host+cr.path, body)
 	if err != nil {
-		return Error{fmt.Sprintf("Could not create request: %s", err), defaultCode}
+		return 0, 0, Error{fmt.Sprintf("Could not create request: %s", err), defaultCode}
 	}
 
 	req.Header.Add("Content-Type", ct)
 	req.Header.Add("Accept", "application/json")
 	req.Header.Add("Accept-Charset", "utf-8")
-	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", m.AccessToken))
+
+	// cr.skipAuth requests (currently just acquireAccessToken's own
+	// token exchange) authenticate via client_id/client_secret in the
+	// body instead, and must not go through m.bearerToken(): that would
+	// call m.TokenSource.Token(), which for a refreshingTokenSource
+	// refreshing via this very request would deadlock on its own mutex.
+	if !cr.skipAuth {
+		token, err := m.bearerToken()
+		if err != nil {
+			return 0, 0, err
+		}
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", token))
+	}
 
 	// Create the HTTP client
 	client := &http.Client{
@@ -412,41 +979,194 @@ func (m *Medium) request(cr clientRequest, result interface{}) error {
 	// Make the request
 	res, err := client.Do(req)
 	if err != nil {
-		return Error{fmt.Sprintf("Failed to make request: %s", err), defaultCode}
+		// Wrapped as an APIError (rather than a bare Error) with a zero
+		// HTTPStatus so isRetryable and Classify can recognize this as a
+		// transport-level failure that never reached the server, as
+		// opposed to a permanent request-construction error.
+		return 0, 0, APIError{Err: Error{fmt.Sprintf("Failed to make request: %s", err), defaultCode}}
 	}
 	defer res.Body.Close()
 
+	retryAfter := parseRetryAfter(res.Header.Get("Retry-After"))
+	rateLimit := parseRateLimit(res.Header)
+
 	// Parse the response
 	c, err := ioutil.ReadAll(res.Body)
 	if err != nil {
-		return Error{fmt.Sprintf("Could not read response: %s", err), defaultCode}
+		return res.StatusCode, retryAfter, APIError{
+			Err:        Error{fmt.Sprintf("Could not read response: %s", err), defaultCode},
+			HTTPStatus: res.StatusCode,
+			Header:     res.Header,
+			RateLimit:  rateLimit,
+		}
 	}
 
 	var env envelope
 	if err := json.Unmarshal(c, &env); err != nil {
-		return Error{fmt.Sprintf("Could not parse response: %s", err), defaultCode}
+		return res.StatusCode, retryAfter, APIError{
+			Err:        Error{fmt.Sprintf("Could not parse response: %s", err), defaultCode},
+			HTTPStatus: res.StatusCode,
+			Header:     res.Header,
+			RawBody:    c,
+			RateLimit:  rateLimit,
+		}
 	}
 
 	if http.StatusOK <= res.StatusCode && res.StatusCode < http.StatusMultipleChoices {
+		if cr.nextCursor != nil && env.Paging != nil {
+			*cr.nextCursor = env.Paging.Next
+		}
+		if result == nil {
+			return res.StatusCode, 0, nil
+		}
 		if env.Data != nil {
 			c, _ = json.Marshal(env.Data)
 		}
-		return json.Unmarshal(c, &result)
+		return res.StatusCode, 0, json.Unmarshal(c, &result)
 	}
 	e := env.Errors[0]
-	return Error{e.Message, e.Code}
+	return res.StatusCode, retryAfter, APIError{
+		Err:        Error{e.Message, e.Code},
+		HTTPStatus: res.StatusCode,
+		Header:     res.Header,
+		RawBody:    c,
+		RateLimit:  rateLimit,
+	}
+}
+
+// parseRetryAfter parses an HTTP Retry-After header, which may be either
+// a number of seconds or an HTTP date, returning zero if it is absent or
+// unparseable.
+func parseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// retryBackoff returns m.RetryBackoff, falling back to
+// defaultRetryBackoff if the caller hasn't configured one.
+func (m *Medium) retryBackoff() func(int) time.Duration {
+	if m.RetryBackoff != nil {
+		return m.RetryBackoff
+	}
+	return defaultRetryBackoff
+}
+
+// retryableStatusCodes returns m.RetryableStatusCodes, falling back to
+// defaultRetryableStatusCodes if the caller hasn't configured one.
+func (m *Medium) retryableStatusCodes() map[int]bool {
+	if m.RetryableStatusCodes != nil {
+		return m.RetryableStatusCodes
+	}
+	return defaultRetryableStatusCodes
+}
+
+// isRetryable reports whether a failed attempt that observed statusCode
+// (0 if the request never reached the server) and err should be retried.
+// With a zero statusCode, only a transport-level failure (an APIError
+// with no HTTPStatus, as doRequest returns when the request could not be
+// sent) is retried; request-construction and encoding errors (malformed
+// URLs, JSON marshalling, an unknown format) are bare Errors, and a
+// failed token refresh carries the HTTPStatus of the token exchange, so
+// these are never retried, since retrying them would just fail the same
+// way again.
+func (m *Medium) isRetryable(statusCode int, err error) bool {
+	if statusCode != 0 {
+		return m.retryableStatusCodes()[statusCode]
+	}
+	var ae APIError
+	return errors.As(err, &ae) && ae.HTTPStatus == 0
+}
+
+// defaultRetryBackoff computes an exponential backoff duration with
+// jitter for the given retry attempt (0-indexed).
+func defaultRetryBackoff(attempt int) time.Duration {
+	d := retryBaseDelay << uint(attempt)
+	if d <= 0 || d > retryMaxDelay {
+		d = retryMaxDelay
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d)/2+1))
+}
+
+// defaultRetryableStatusCodes are the HTTP status codes considered
+// transient by default: 429 (rate limited) and the 500, 502, 503 and 504
+// server errors.
+var defaultRetryableStatusCodes = map[int]bool{
+	http.StatusTooManyRequests:     true,
+	http.StatusInternalServerError: true,
+	http.StatusBadGateway:          true,
+	http.StatusServiceUnavailable:  true,
+	http.StatusGatewayTimeout:      true,
+}
+
+// RetryAction describes how a caller should respond to an error returned
+// from a Medium API call.
+type RetryAction int
+
+const (
+	// RetryActionFail indicates the error is not transient; the caller
+	// should give up.
+	RetryActionFail RetryAction = iota
+	// RetryActionRetry indicates the request may succeed if retried,
+	// ideally after a backoff.
+	RetryActionRetry
+	// RetryActionReauth indicates the AccessToken was rejected and the
+	// caller should refresh it (e.g. via TokenSource or
+	// ExchangeRefreshToken) before retrying.
+	RetryActionReauth
+)
+
+// Classify reports how a caller should respond to err, so that custom
+// retry loops can be built around typed classification rather than
+// matching on err.Error().
+func Classify(err error) RetryAction {
+	if err == nil {
+		return RetryActionFail
+	}
+	var ae APIError
+	if errors.As(err, &ae) {
+		switch {
+		case ae.HTTPStatus == 0:
+			// Only doRequest's transport-level failure wraps an APIError
+			// with no HTTPStatus: the request never reached the server,
+			// so it's generally worth retrying.
+			return RetryActionRetry
+		case ae.IsUnauthorized():
+			return RetryActionReauth
+		case ae.IsRateLimited(), ae.IsServerError():
+			return RetryActionRetry
+		default:
+			return RetryActionFail
+		}
+	}
+	if _, ok := err.(Error); ok {
+		return RetryActionFail
+	}
+	// Fall back to retrying anything else (e.g. an error from a caller's
+	// own wrapping) rather than failing silently on an unknown type.
+	return RetryActionRetry
 }
 
 // acquireAccessToken makes a request to Medium for an access token.
-func (m *Medium) acquireAccessToken(v url.Values) (AccessToken, error) {
+func (m *Medium) acquireAccessToken(ctx context.Context, v url.Values) (AccessToken, error) {
 	cr := clientRequest{
-		method: "POST",
-		path:   "/v1/tokens",
-		format: formatForm,
-		data:   v.Encode(),
+		method:   "POST",
+		path:     "/v1/tokens",
+		format:   formatForm,
+		data:     v.Encode(),
+		skipAuth: true,
 	}
 	at := AccessToken{}
-	err := m.request(cr, &at)
+	err := m.requestContext(ctx, cr, &at)
 
 	// Set the access token on the service.
 	if err == nil {
@@ -466,14 +1186,30 @@ type clientRequest struct {
 	path   string
 	data   interface{}
 	format string
+
+	// nextCursor, if set, receives the pagination cursor for the next
+	// page from the response envelope's paging.next, once the request
+	// succeeds.
+	nextCursor *string
+
+	// skipAuth marks a request that authenticates itself (e.g. via
+	// client_id/client_secret in its body) and so must not go through
+	// m.bearerToken()/m.TokenSource. See doRequest.
+	skipAuth bool
 }
 
 // payload defines a struct to represent payloads that are returned from Medium.
 type envelope struct {
 	Data   interface{} `json:"data"`
+	Paging *paging     `json:"paging,omitempty"`
 	Errors []Error     `json:"errors,omitempty"`
 }
 
+// paging carries the cursor for the next page of a list endpoint.
+type paging struct {
+	Next string `json:"next"`
+}
+
 // osFS is an implementation of fileOpener that uses the disk.
 type osFS struct{}
 
@@ -481,7 +1217,7 @@ type osFS struct{}
 func (osFS) Open(name string) (io.ReadCloser, error) { return os.Open(name) }
 
 // requestDataGenerator defines a function that can generate request data.
-type requestDataGenerator func(cr clientRequest) ([]byte, string, error)
+type requestDataGenerator func(cr clientRequest) (io.Reader, string, error)
 
 // Borrowed from multipart/writer.go
 var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
@@ -4,6 +4,7 @@ package medium
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -12,6 +13,7 @@ import (
 	"reflect"
 	"regexp"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -24,9 +26,12 @@ func (fakeFS) Open(name string) (io.ReadCloser, error) {
 }
 
 type apiTest struct {
-	token       string
-	fn          interface{}
+	token string
+	fn    interface{}
+	// payload is passed as the function's sole argument. For a function
+	// that takes more than one argument, set payloads instead.
 	payload     interface{}
+	payloads    []interface{}
 	method      string
 	path        string
 	contentType string
@@ -36,24 +41,42 @@ type apiTest struct {
 var m = NewClient("clientId", "clientSecret")
 
 var apiTests = []apiTest{
-	{"token", m.GetUser, "",
-		"GET", "/v1/me", "application/json",
-		"null"},
-	{"token", m.GetUser, "@dummyUser",
-		"GET", "/v1/@dummyUser", "application/json",
-		"null"},
-	{"token", m.GetUserPublications, "@dummyUser",
-		"GET", "/v1/users/@dummyUser/publications", "application/json",
-		"null"},
-	{"token", m.GetPublicationContributors, "b45573563f5a",
-		"GET", "/v1/publications/b45573563f5a/contributors", "application/json",
-		"null"},
-	{"token", m.CreatePost, CreatePostOptions{UserID: "42", Title: "Title", Content: "Yo", ContentFormat: "html"},
-		"POST", "/v1/users/42/posts", "application/json",
-		`{"title":"Title","content":"Yo","contentFormat":"html"}`},
-	{"token", m.UploadImage, UploadOptions{FilePath: "/fake/file.png", ContentType: "image/png"},
-		"POST", "/v1/images", "multipart/form-data.*",
-		`^--[a-z0-9]+\r\n(Content-Disposition: form-data; name="image"; filename="file.png"|Content-Type: image/png)\r\n(Content-Disposition: form-data; name="image"; filename="file.png"|Content-Type: image/png)\r\n\r\ncontents\r\n--[a-z0-9]+--\r\n$`},
+	{token: "token", fn: m.GetUser, payload: "",
+		method: "GET", path: "/v1/me", contentType: "application/json",
+		bodyPattern: "null"},
+	{token: "token", fn: m.GetUser, payload: "@dummyUser",
+		method: "GET", path: "/v1/@dummyUser", contentType: "application/json",
+		bodyPattern: "null"},
+	{token: "token", fn: m.GetUserPublications, payload: "@dummyUser",
+		method: "GET", path: "/v1/users/@dummyUser/publications", contentType: "application/json",
+		bodyPattern: "null"},
+	{token: "token", fn: m.GetPublicationContributors, payload: "b45573563f5a",
+		method: "GET", path: "/v1/publications/b45573563f5a/contributors", contentType: "application/json",
+		bodyPattern: "null"},
+	{token: "token", fn: m.CreatePost, payload: CreatePostOptions{UserID: "42", Title: "Title", Content: "Yo", ContentFormat: "html"},
+		method: "POST", path: "/v1/users/42/posts", contentType: "application/json",
+		bodyPattern: `{"title":"Title","content":"Yo","contentFormat":"html"}`},
+	{token: "token", fn: m.CreatePostInPublication, payloads: []interface{}{"pub123", CreatePostOptions{Title: "Title", Content: "Yo", ContentFormat: "html"}},
+		method: "POST", path: "/v1/publications/pub123/posts", contentType: "application/json",
+		bodyPattern: `{"title":"Title","content":"Yo","contentFormat":"html"}`},
+	{token: "token", fn: m.GetPost, payload: "42",
+		method: "GET", path: "/v1/posts/42", contentType: "application/json",
+		bodyPattern: "null"},
+	{token: "token", fn: m.UpdatePost, payload: UpdatePostOptions{PostID: "42", Title: "New title"},
+		method: "PUT", path: "/v1/posts/42", contentType: "application/json",
+		bodyPattern: `{"title":"New title"}`},
+	{token: "token", fn: m.DeletePost, payload: "42",
+		method: "DELETE", path: "/v1/posts/42", contentType: "application/json",
+		bodyPattern: "null"},
+	{token: "token", fn: m.ListUserPosts, payloads: []interface{}{"@dummyUser", ListOptions{Limit: 10}},
+		method: "GET", path: "/v1/users/@dummyUser/posts", contentType: "application/json",
+		bodyPattern: "null"},
+	{token: "token", fn: m.ListPublicationPosts, payloads: []interface{}{"pub123", ListOptions{}},
+		method: "GET", path: "/v1/publications/pub123/posts", contentType: "application/json",
+		bodyPattern: "null"},
+	{token: "token", fn: m.UploadImage, payload: UploadOptions{FilePath: "/fake/file.png", ContentType: "image/png"},
+		method: "POST", path: "/v1/images", contentType: "multipart/form-data.*",
+		bodyPattern: `^--[a-z0-9]+\r\n(Content-Disposition: form-data; name="image"; filename="file.png"|Content-Type: image/png)\r\n(Content-Disposition: form-data; name="image"; filename="file.png"|Content-Type: image/png)\r\n\r\ncontents\r\n--[a-z0-9]+--\r\n$`},
 }
 
 // TestAPIMethods tests that http requests are constructed correctly.
@@ -73,7 +96,12 @@ func TestAPIMethods(t *testing.T) {
 
 		f := reflect.ValueOf(tt.fn)
 		var pl []reflect.Value
-		if tt.payload != nil {
+		switch {
+		case tt.payloads != nil:
+			for _, p := range tt.payloads {
+				pl = append(pl, reflect.ValueOf(p))
+			}
+		case tt.payload != nil:
 			pl = append(pl, reflect.ValueOf(tt.payload))
 		}
 		f.Call(pl)
@@ -110,6 +138,245 @@ func TestAPITimeout(t *testing.T) {
 	}
 }
 
+// flakyTransport fails the first n RoundTrips with a network error before
+// delegating to the real transport, simulating a dropped connection.
+type flakyTransport struct {
+	failures int
+	attempts int32
+}
+
+func (f *flakyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if atomic.AddInt32(&f.attempts, 1) <= int32(f.failures) {
+		return nil, errors.New("simulated network error")
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// TestRequestContextRetriesNetworkError verifies that a request which
+// never reaches the server (a transport-level failure) is retried like
+// any other transient failure, rather than being treated as permanent.
+func TestRequestContextRetriesNetworkError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"data":{"id":"user1"}}`)
+	}))
+	defer ts.Close()
+
+	ft := &flakyTransport{failures: 2}
+	client := NewClientWithAccessToken("token")
+	client.Host = ts.URL
+	client.Transport = ft
+	client.MaxRetries = 2
+	client.RetryBackoff = func(attempt int) time.Duration { return time.Millisecond }
+
+	u, err := client.GetUser("")
+	if err != nil {
+		t.Fatalf("expected retries to recover from network errors, got %s", err)
+	}
+	if u.ID != "user1" {
+		t.Errorf("Expected user1, got %#v", u)
+	}
+	if got := atomic.LoadInt32(&ft.attempts); got != 3 {
+		t.Errorf("Expected 3 attempts (2 failures + 1 success), got %d", got)
+	}
+}
+
+// TestClassify verifies that a transport-level failure (an APIError with
+// no HTTPStatus) is classified as retryable, while a permanent
+// request-construction error is not.
+func TestClassify(t *testing.T) {
+	networkErr := APIError{Err: Error{"simulated network error", defaultCode}}
+	if got := Classify(networkErr); got != RetryActionRetry {
+		t.Errorf("Classify(network APIError) = %v, want RetryActionRetry", got)
+	}
+
+	constructionErr := Error{"bad request", defaultCode}
+	if got := Classify(constructionErr); got != RetryActionFail {
+		t.Errorf("Classify(Error) = %v, want RetryActionFail", got)
+	}
+}
+
+// onceReader wraps an io.Reader without exposing io.Seeker, simulating a
+// caller-supplied stream that can only be read once.
+type onceReader struct {
+	r io.Reader
+}
+
+func (o *onceReader) Read(b []byte) (int, error) { return o.r.Read(b) }
+
+// TestUploadImageNonSeekableReaderSkipsRetry verifies that a retry is
+// never attempted for an UploadOptions.Reader that can't be rewound,
+// rather than resending whatever was left of the already-consumed
+// Reader.
+func TestUploadImageNonSeekableReaderSkipsRetry(t *testing.T) {
+	var attempts int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprintln(w, `{"errors":[{"message":"unavailable","code":1}]}`)
+	}))
+	defer ts.Close()
+
+	client := NewClientWithAccessToken("token")
+	client.Host = ts.URL
+	client.MaxRetries = 3
+	client.RetryBackoff = func(attempt int) time.Duration { return time.Millisecond }
+
+	_, err := client.UploadImage(UploadOptions{
+		Reader:      &onceReader{r: strings.NewReader("image-bytes")},
+		ContentType: "image/png",
+	})
+	if err == nil {
+		t.Fatal("Expected upload to fail rather than retry a non-seekable Reader")
+	}
+	if got := atomic.LoadInt32(&attempts); got != 1 {
+		t.Errorf("Expected exactly 1 attempt for a non-seekable Reader, got %d", got)
+	}
+}
+
+// TestUploadImageSeekableReaderRetries verifies that a retry rewinds and
+// fully resends an UploadOptions.Reader that implements io.Seeker.
+func TestUploadImageSeekableReaderRetries(t *testing.T) {
+	var attempts int32
+	var lastBody string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		lastBody = string(b)
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprintln(w, `{"errors":[{"message":"unavailable","code":1}]}`)
+			return
+		}
+		fmt.Fprintln(w, `{"data":{"url":"http://img","md5":"abc"}}`)
+	}))
+	defer ts.Close()
+
+	client := NewClientWithAccessToken("token")
+	client.Host = ts.URL
+	client.MaxRetries = 1
+	client.RetryBackoff = func(attempt int) time.Duration { return time.Millisecond }
+
+	_, err := client.UploadImage(UploadOptions{
+		Reader:      bytes.NewReader([]byte("image-bytes")),
+		ContentType: "image/png",
+	})
+	if err != nil {
+		t.Fatalf("Expected retry to succeed, got %s", err)
+	}
+	if !strings.Contains(lastBody, "image-bytes") {
+		t.Errorf("Expected retried upload to resend the full body, got %q", lastBody)
+	}
+}
+
+// TestTokenSourceRefreshDoesNotDeadlock verifies that a call made through
+// a refreshingTokenSource whose token is due for refresh can complete: a
+// naive implementation that holds its mutex across the refresh's own
+// token-exchange request (which itself calls back into the same
+// TokenSource) deadlocks forever.
+func TestTokenSourceRefreshDoesNotDeadlock(t *testing.T) {
+	var exchanges int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/tokens" {
+			n := atomic.AddInt32(&exchanges, 1)
+			expiresAt := time.Now().Add(time.Hour).UnixNano() / int64(time.Millisecond)
+			fmt.Fprintf(w, `{"data":{"access_token":"fresh-%d","refresh_token":"refresh","expires_at":%d}}`, n, expiresAt)
+			return
+		}
+		fmt.Fprintln(w, `{"data":{"id":"user1"}}`)
+	}))
+	defer ts.Close()
+
+	client := NewClient("id", "secret")
+	client.Host = ts.URL
+	expired := AccessToken{
+		AccessToken:  "stale",
+		RefreshToken: "refresh",
+		ExpiresAt:    time.Now().Add(-time.Hour).UnixNano() / int64(time.Millisecond),
+	}
+	client.TokenSource = NewTokenSource(client, expired, 0)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.GetUser("")
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("GetUser returned an error: %s", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("GetUser did not return: refreshingTokenSource deadlocked")
+	}
+	if got := atomic.LoadInt32(&exchanges); got != 1 {
+		t.Errorf("Expected exactly 1 token exchange, got %d", got)
+	}
+}
+
+// TestAPIErrorUnauthorized verifies that a 401 response is surfaced as
+// an APIError matching ErrTokenExpired via errors.Is, and classified as
+// needing reauthentication rather than a retry.
+func TestAPIErrorUnauthorized(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprintln(w, `{"errors":[{"message":"token expired","code":1}]}`)
+	}))
+	defer ts.Close()
+
+	client := NewClientWithAccessToken("token")
+	client.Host = ts.URL
+
+	_, err := client.GetUser("")
+	if err == nil {
+		t.Fatal("expected an error for a 401 response")
+	}
+	if !errors.Is(err, ErrTokenExpired) {
+		t.Errorf("expected errors.Is(err, ErrTokenExpired), got %s", err)
+	}
+	var ae APIError
+	if !errors.As(err, &ae) {
+		t.Fatalf("expected an APIError, got %T", err)
+	}
+	if !ae.IsUnauthorized() {
+		t.Errorf("expected IsUnauthorized() to be true for status %d", ae.HTTPStatus)
+	}
+	if got := Classify(err); got != RetryActionReauth {
+		t.Errorf("Classify(401) = %v, want RetryActionReauth", got)
+	}
+}
+
+// TestAPIErrorRateLimited verifies that a 429 response's X-RateLimit-*
+// headers are parsed into APIError.RateLimit, and that the error is
+// classified as retryable.
+func TestAPIErrorRateLimited(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "100")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", "1700000000")
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprintln(w, `{"errors":[{"message":"rate limited","code":2}]}`)
+	}))
+	defer ts.Close()
+
+	client := NewClientWithAccessToken("token")
+	client.Host = ts.URL
+
+	_, err := client.GetUser("")
+	var ae APIError
+	if !errors.As(err, &ae) {
+		t.Fatalf("expected an APIError, got %T (%s)", err, err)
+	}
+	if !ae.IsRateLimited() {
+		t.Errorf("expected IsRateLimited() to be true for status %d", ae.HTTPStatus)
+	}
+	if ae.RateLimit.Limit != 100 || ae.RateLimit.Remaining != 0 {
+		t.Errorf("unexpected RateLimit: %+v", ae.RateLimit)
+	}
+	if got := Classify(err); got != RetryActionRetry {
+		t.Errorf("Classify(429) = %v, want RetryActionRetry", got)
+	}
+}
+
 func assertEqual(t *testing.T, actual, expected interface{}) {
 	if actual != expected {
 		t.Errorf("Expected %#v, got %#v", expected, actual)
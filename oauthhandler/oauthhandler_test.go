@@ -0,0 +1,89 @@
+// Copyright 2015 A Medium Corporation
+
+package oauthhandler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	medium "github.com/Medium/medium-sdk-go"
+)
+
+// TestLoginCallbackSendsCodeVerifier drives LoginHandler and
+// CallbackHandler back to back, the way a browser following the
+// redirect would, and verifies that the code_verifier LoginHandler
+// committed to in the authorize URL's code_challenge is the one
+// CallbackHandler actually sends when exchanging the code.
+func TestLoginCallbackSendsCodeVerifier(t *testing.T) {
+	var gotVerifier string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/tokens" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		r.ParseForm()
+		gotVerifier = r.Form.Get("code_verifier")
+		fmt.Fprintln(w, `{"data":{"access_token":"tok"}}`)
+	}))
+	defer ts.Close()
+
+	client := medium.NewClient("id", "secret")
+	client.Host = ts.URL
+	h := New(client, []byte("signing-key"))
+
+	loginRec := httptest.NewRecorder()
+	h.LoginHandler(nil, "https://example.com/callback").ServeHTTP(loginRec, httptest.NewRequest("GET", "/login", nil))
+	loginResp := loginRec.Result()
+
+	authURL, err := url.Parse(loginResp.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("could not parse authorize URL: %s", err)
+	}
+	wantVerifier := cookieValue(t, loginResp.Cookies(), verifierCookieName, h)
+	wantChallenge := codeChallengeS256(wantVerifier)
+	if got := authURL.Query().Get("code_challenge"); got != wantChallenge {
+		t.Errorf("code_challenge = %q, want %q", got, wantChallenge)
+	}
+	if got := authURL.Query().Get("code_challenge_method"); got != "S256" {
+		t.Errorf("code_challenge_method = %q, want S256", got)
+	}
+
+	callbackReq := httptest.NewRequest("GET", "/callback?state="+authURL.Query().Get("state")+"&code=authcode", nil)
+	for _, c := range loginResp.Cookies() {
+		callbackReq.AddCookie(c)
+	}
+
+	var gotToken medium.AccessToken
+	callbackRec := httptest.NewRecorder()
+	h.CallbackHandler("https://example.com/callback", func(w http.ResponseWriter, r *http.Request, at medium.AccessToken) {
+		gotToken = at
+	}).ServeHTTP(callbackRec, callbackReq)
+
+	if callbackRec.Code != http.StatusOK {
+		t.Fatalf("callback failed: %d %s", callbackRec.Code, callbackRec.Body.String())
+	}
+	if gotToken.AccessToken != "tok" {
+		t.Errorf("unexpected access token: %+v", gotToken)
+	}
+	if gotVerifier != wantVerifier {
+		t.Fatalf("token exchange sent code_verifier %q, want %q", gotVerifier, wantVerifier)
+	}
+}
+
+// cookieValue extracts and verifies a signed cookie from cookies, the
+// same way CallbackHandler does.
+func cookieValue(t *testing.T, cookies []*http.Cookie, name string, h *Handler) string {
+	t.Helper()
+	r := httptest.NewRequest("GET", "/", nil)
+	for _, c := range cookies {
+		r.AddCookie(c)
+	}
+	v, ok := h.readSignedCookie(r, name)
+	if !ok {
+		t.Fatalf("missing or invalid %s cookie", name)
+	}
+	return v
+}